@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"backend/internal/model"
+	"backend/internal/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ColumnSettingHandler は一覧カラム設定の get/upsert/reset/list を1エンドポイントで扱う。
+// action クエリパラメータで操作を切り替える（例: POST /api/column-settings?action=upsert）。
+type ColumnSettingHandler struct {
+	columnSettingService *service.ColumnSettingService
+}
+
+func NewColumnSettingHandler(columnSettingService *service.ColumnSettingService) *ColumnSettingHandler {
+	return &ColumnSettingHandler{columnSettingService: columnSettingService}
+}
+
+func (h *ColumnSettingHandler) HandleColumnSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("action") {
+	case "list":
+		h.handleList(w, r)
+	case "reset":
+		h.handleReset(w, r)
+	case "upsert":
+		h.handleUpsert(w, r)
+	case "get", "":
+		h.handleGet(w, r)
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+	}
+}
+
+// handleList は設定可能なモジュールキーと、それぞれのデフォルトカラム ID 一覧を返す
+func (h *ColumnSettingHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.columnSettingService.List())
+}
+
+func (h *ColumnSettingHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	userID, key, ok := parseUserAndKey(w, r)
+	if !ok {
+		return
+	}
+
+	setting, err := h.columnSettingService.Get(r.Context(), userID, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, setting)
+}
+
+func (h *ColumnSettingHandler) handleReset(w http.ResponseWriter, r *http.Request) {
+	userID, key, ok := parseUserAndKey(w, r)
+	if !ok {
+		return
+	}
+
+	setting, err := h.columnSettingService.Reset(r.Context(), userID, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, setting)
+}
+
+func (h *ColumnSettingHandler) handleUpsert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var setting model.ColumnSetting
+	if err := json.NewDecoder(r.Body).Decode(&setting); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.columnSettingService.Upsert(r.Context(), setting); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, setting)
+}
+
+func parseUserAndKey(w http.ResponseWriter, r *http.Request) (int, string, bool) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return 0, "", false
+	}
+
+	userID, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return 0, "", false
+	}
+	return userID, key, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
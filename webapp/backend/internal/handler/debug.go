@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"backend/internal/observability"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// adminTokenEnv は POST /admin/debug を叩く際に一致させる必要がある共有シークレットの
+// 環境変数名。このリポジトリには管理者セッション/認証の仕組みがまだ無いため、ひとまず
+// 固定トークンでゲートする。本番では加えてリバースプロキシ等で経路自体も制限すること。
+const adminTokenEnv = "ADMIN_API_TOKEN"
+
+// HandleDebugToggle は POST /admin/debug を処理し、observability.IsDebug を
+// 再デプロイなしで切り替える。body: {"enabled": true}
+// Authorization: Bearer <ADMIN_API_TOKEN> が一致しない限り拒否する（ADMIN_API_TOKEN が
+// 未設定の環境では誰も呼べないよう、デフォルトで閉じる）。
+func HandleDebugToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isAuthorizedAdmin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var body struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	observability.SetDebug(body.Enabled)
+	writeJSON(w, map[string]bool{"enabled": observability.Enabled()})
+}
+
+// isAuthorizedAdmin は Authorization: Bearer <token> が ADMIN_API_TOKEN と一致するかを
+// 定数時間比較で確認する。ADMIN_API_TOKEN が設定されていない場合は常に拒否する。
+func isAuthorizedAdmin(r *http.Request) bool {
+	expected := os.Getenv(adminTokenEnv)
+	if expected == "" {
+		return false
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	got := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(expected)) == 1
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"backend/internal/service"
+	"encoding/json"
+	"net/http"
+)
+
+// maxImportUploadSize は multipart フォームとしてメモリに読み込む上限サイズ
+const maxImportUploadSize = 20 << 20 // 20MB
+
+// ImportHandler は multipart/form-data で受け取った Excel ファイルを
+// ImportService に流し込み、インポート結果を JSON で返す。
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// HandleImport は POST /api/import を処理する。
+// multipart フォームの "file" に Excel ファイル、"code" に "PRODUCTS" | "ORDERS" を期待する。
+// 行単位のエラーは fail-fast せず、成功件数とあわせて1つの JSON レスポンスで返す。
+func (h *ImportHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxImportUploadSize); err != nil {
+		http.Error(w, "invalid multipart form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	code := r.FormValue("code")
+	if code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.Import(r.Context(), code, file)
+	if err != nil {
+		// BulkInsert の途中で失敗した場合、result にはそれまでにコミット済みの件数が入っている。
+		// 破棄せず、呼び出し側がどこまで入ったか分かるようエラーと一緒に返す
+		// （投入前の段階で失敗した場合 result は nil で、error だけが返る）。
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(struct {
+			*service.ImportResult
+			Error string `json:"error"`
+		}{ImportResult: result, Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
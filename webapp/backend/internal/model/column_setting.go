@@ -0,0 +1,17 @@
+package model
+
+// ColumnDef は一覧画面の1カラムに対する表示設定
+type ColumnDef struct {
+	ColumnID string `json:"column_id"`
+	ParamCn  string `json:"param_cn"`
+	ParamFix int    `json:"param_fix"`
+	Visible  bool   `json:"visible"`
+	Order    int    `json:"order"`
+}
+
+// ColumnSetting はユーザー1人・モジュール(Key)1つ分のカラム表示設定
+type ColumnSetting struct {
+	UserID  int         `json:"user_id"`
+	Key     string      `json:"key"`
+	Columns []ColumnDef `json:"columns"`
+}
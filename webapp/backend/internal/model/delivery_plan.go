@@ -0,0 +1,21 @@
+package model
+
+// DeliveryPlan は1台のロボットに対して確定した配送計画
+type DeliveryPlan struct {
+	RobotID     string  `json:"robot_id"`
+	TotalWeight int     `json:"total_weight"`
+	TotalValue  int     `json:"total_value"`
+	Orders      []Order `json:"orders"`
+	// Algorithm は選択アルゴリズム ("exact_full" | "dp" | "branch_and_bound" |
+	// "branch_and_bound_partial" | "greedy_fallback") を示す。
+	// 観測性のために記録するだけで、プランの妥当性には影響しない。
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// RobotSpec は複数ロボット同時配車リクエストの1台分の仕様
+type RobotSpec struct {
+	RobotID  string `json:"robot_id"`
+	Capacity int    `json:"capacity"`
+	// AllowPartial は注文の一部数量だけを積んで残りを shipping に残すことを許可するか
+	AllowPartial bool `json:"allow_partial"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Order は orders テーブル1行分のデータを表す
+type Order struct {
+	OrderID       int64        `db:"order_id" json:"order_id"`
+	UserID        int          `db:"user_id" json:"user_id,omitempty"`
+	ProductID     int          `db:"product_id" json:"product_id"`
+	ProductName   string       `db:"product_name" json:"product_name,omitempty"`
+	ShippedStatus string       `db:"shipped_status" json:"shipped_status"`
+	Weight        int          `db:"weight" json:"weight,omitempty"`
+	Value         int          `db:"value" json:"value,omitempty"`
+	// Quantity はこの注文行がまだ配送待ちとして抱えている個数。未設定(0)の行は1個として扱う。
+	Quantity int `db:"quantity" json:"quantity,omitempty"`
+	// DeliveredQuantity は SplitOrder で確保された個数。確保された行では Quantity と同じ値になり
+	// （残数量は別の注文行に移る）、SplitOrder を経ていない行では 0 のまま。
+	DeliveredQuantity int          `db:"delivered_quantity" json:"delivered_quantity,omitempty"`
+	CreatedAt         time.Time    `db:"created_at" json:"created_at,omitempty"`
+	ArrivedAt         sql.NullTime `db:"arrived_at" json:"arrived_at,omitempty"`
+}
+
+// ListRequest は注文・商品一覧 API に共通の検索/並び替え/ページングパラメータ
+type ListRequest struct {
+	Search    string
+	Type      string
+	SortField string
+	SortOrder string
+	PageSize  int
+	Offset    int
+}
@@ -0,0 +1,11 @@
+package model
+
+// Product は products テーブル1行分のデータを表す
+type Product struct {
+	ProductID   int    `db:"product_id" json:"product_id"`
+	Name        string `db:"name" json:"name"`
+	Value       int    `db:"value" json:"value"`
+	Weight      int    `db:"weight" json:"weight"`
+	Image       string `db:"image" json:"image,omitempty"`
+	Description string `db:"description" json:"description,omitempty"`
+}
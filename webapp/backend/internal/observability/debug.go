@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// IsDebug はデバッグログ出力の ON/OFF を実行時に切り替えるフラグ（0=OFF, 1=ON）。
+// デプロイし直さずに POST /admin/debug から切り替えられるように atomic で保持する。
+var IsDebug int32
+
+// SetDebug はフラグを切り替える
+func SetDebug(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&IsDebug, v)
+}
+
+// Enabled は現在デバッグログが有効かどうかを返す
+func Enabled() bool {
+	return atomic.LoadInt32(&IsDebug) == 1
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID は ctx に相関IDを埋め込む。1件のリクエストに1つ払い出すことで、
+// SELECT・ナップサック計算・UPDATE をまたいで同じ処理をログ上で追えるようにする。
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID は ctx に埋め込まれた相関IDを返す。未設定なら空文字列。
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// Debug は IsDebug が有効な場合にのみ、msg とフィールド群を1行にまとめて出力する。
+// fields は key, value, key, value, ... の順で渡す。string 以外の value は JSON エンコードする。
+func Debug(ctx context.Context, msg string, fields ...interface{}) {
+	if !Enabled() {
+		return
+	}
+
+	parts := make([]string, 0, len(fields)/2+2)
+	parts = append(parts, fmt.Sprintf("ts=%s", time.Now().Format(time.RFC3339Nano)))
+	if cid := CorrelationID(ctx); cid != "" {
+		parts = append(parts, fmt.Sprintf("correlation_id=%s", cid))
+	}
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		parts = append(parts, fmt.Sprintf("%v=%s", fields[i], encodeValue(fields[i+1])))
+	}
+
+	log.Printf("[DEBUG] %s %s", msg, strings.Join(parts, " "))
+}
+
+func encodeValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
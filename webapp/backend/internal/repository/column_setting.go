@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"backend/internal/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type ColumnSettingRepository struct {
+	db DBTX
+}
+
+func NewColumnSettingRepository(db DBTX) *ColumnSettingRepository {
+	return &ColumnSettingRepository{db: db}
+}
+
+// Get はユーザーが保存したカラム設定を取得する。未保存の場合は nil, nil を返す
+// （デフォルト設定を返すかどうかは呼び出し側=サービス層の責務とする）。
+func (r *ColumnSettingRepository) Get(ctx context.Context, userID int, key string) (*model.ColumnSetting, error) {
+	var row struct {
+		ColumnsJSON string `db:"columns_json"`
+	}
+	err := r.db.GetContext(ctx, &row,
+		"SELECT columns_json FROM column_settings WHERE user_id = ? AND `key` = ?",
+		userID, key,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []model.ColumnDef
+	if err := json.Unmarshal([]byte(row.ColumnsJSON), &columns); err != nil {
+		return nil, err
+	}
+	return &model.ColumnSetting{UserID: userID, Key: key, Columns: columns}, nil
+}
+
+// Upsert はユーザーのカラム設定を保存（既存なら上書き）する
+func (r *ColumnSettingRepository) Upsert(ctx context.Context, setting model.ColumnSetting) error {
+	columnsJSON, err := json.Marshal(setting.Columns)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO column_settings (user_id, `key`, columns_json, updated_at) VALUES (?, ?, ?, NOW()) "+
+			"ON DUPLICATE KEY UPDATE columns_json = VALUES(columns_json), updated_at = VALUES(updated_at)",
+		setting.UserID, setting.Key, string(columnsJSON),
+	)
+	return err
+}
+
+// Reset はユーザーの保存済みカラム設定を削除し、デフォルトに戻す
+func (r *ColumnSettingRepository) Reset(ctx context.Context, userID int, key string) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM column_settings WHERE user_id = ? AND `key` = ?",
+		userID, key,
+	)
+	return err
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "strings"
+
+// buildProjection は requested に含まれる ColumnID のうち allowList に載っているものだけを
+// SQL 列式に変換して SELECT 句を組み立てる。requested が空、または allowList と1つも
+// 一致しなければ fallback（モジュールのデフォルトカラム）を使う。
+// allowList を経由しない文字列が SQL に混ざることはないため、ColumnID 自体は
+// ユーザー入力でも安全に扱える。
+func buildProjection(requested []string, allowList map[string]string, fallback []string) string {
+	cols := requested
+	if len(cols) == 0 {
+		cols = fallback
+	}
+
+	exprs := make([]string, 0, len(cols))
+	for _, id := range cols {
+		if expr, ok := allowList[id]; ok {
+			exprs = append(exprs, expr)
+		}
+	}
+	if len(exprs) == 0 {
+		for _, id := range fallback {
+			exprs = append(exprs, allowList[id])
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"backend/internal/model"
+	"backend/internal/observability"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -32,6 +35,54 @@ func (r *OrderRepository) Create(ctx context.Context, order *model.Order) (strin
 	return fmt.Sprintf("%d", id), nil
 }
 
+// BulkInsert は orders を batchSize 件ずつの複数行 INSERT に分けて投入し、shipping 状態で
+// 作成した件数を返す。Excel インポートのような大量データ投入で、1行ずつ ExecContext するのを
+// 避けるために使う。
+func (r *OrderRepository) BulkInsert(ctx context.Context, orders []model.Order, batchSize int) (int, error) {
+	if len(orders) == 0 {
+		return 0, nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(orders)
+	}
+
+	inserted := 0
+	for start := 0; start < len(orders); start += batchSize {
+		end := start + batchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+		batch := orders[start:end]
+
+		var queryBuilder strings.Builder
+		queryBuilder.WriteString("INSERT INTO orders (user_id, product_id, quantity, shipped_status, created_at) VALUES ")
+		args := make([]interface{}, 0, len(batch)*3)
+		for i, o := range batch {
+			if i > 0 {
+				queryBuilder.WriteString(", ")
+			}
+			queryBuilder.WriteString("(?, ?, ?, 'shipping', NOW())")
+			args = append(args, o.UserID, o.ProductID, o.Quantity)
+		}
+
+		query, inArgs, err := sqlx.In(queryBuilder.String(), args...)
+		if err != nil {
+			return inserted, err
+		}
+		query = r.db.Rebind(query)
+		result, err := r.db.ExecContext(ctx, query, inArgs...)
+		if err != nil {
+			return inserted, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += int(affected)
+	}
+	return inserted, nil
+}
+
 // 複数の注文IDのステータスを一括で更新
 // 主に配送ロボットが注文を引き受けた際に一括更新をするために使用
 func (r *OrderRepository) UpdateStatuses(ctx context.Context, orderIDs []int64, newStatus string) error {
@@ -75,6 +126,8 @@ func (r *OrderRepository) UpdateStatusesIfCurrentStatus(
     }
 
     query = r.db.Rebind(query)
+
+    start := time.Now()
     res, err := r.db.ExecContext(ctx, query, args...)
     if err != nil {
         return 0, err
@@ -84,28 +137,139 @@ func (r *OrderRepository) UpdateStatusesIfCurrentStatus(
     if err != nil {
         return 0, err
     }
+    observability.Debug(ctx, "OrderRepository.UpdateStatusesIfCurrentStatus",
+        "query", query, "arg_count", len(args), "rows", rows, "elapsed_ms", time.Since(start).Milliseconds())
     return rows, nil
 }
 
 // 配送中(shipped_status:shipping)の注文一覧を取得
+// weight/value は products 側の単価（1個あたり）で、quantity はこの注文行がまだ
+// 配送待ちとして抱えている個数。呼び出し側で合計を求める際は weight*quantity とすること。
 func (r *OrderRepository) GetShippingOrders(ctx context.Context) ([]model.Order, error) {
 	var orders []model.Order
 	query := `
         SELECT
             o.order_id,
             p.weight,
-            p.value
+            p.value,
+            o.quantity
         FROM orders o
         JOIN products p ON o.product_id = p.product_id
         WHERE o.shipped_status = 'shipping'
     `
+	start := time.Now()
 	err := r.db.SelectContext(ctx, &orders, query)
+	observability.Debug(ctx, "OrderRepository.GetShippingOrders",
+		"query", query, "arg_count", 0, "rows", len(orders), "elapsed_ms", time.Since(start).Milliseconds())
 	return orders, err
 }
 
+// ErrOrderNotAvailable は SplitOrder が対象行を確保しようとした時点で、その注文がすでに
+// 'shipping' でなかった（= 別のロボットが先に確保した）ことを示す。呼び出し側は UpdateStatusesIfCurrentStatus
+// と同様にこれを「注文はもう取れなかった」として扱い、該当注文をプランから除外した上で処理を続けること。
+var ErrOrderNotAvailable = errors.New("order not available")
+
+// SplitOrder は注文のうち deliveredQty 個分だけをこのロボットに割り当て、残数量を
+// 新しい注文として shipping プールに戻す。deliveredQty が注文の全数量以上であれば
+// 分割はせず、delivered_quantity を quantity に揃えてステータスを 'delivering' に進める。
+// SELECT と UPDATE の間に別のロボットが同じ注文を確保した場合、UPDATE は0行しか更新せず
+// ErrOrderNotAvailable を返す（楽観的ロック。UpdateStatusesIfCurrentStatus と同じ考え方）。
+// 複数注文にまたがる処理を原子的に行いたい場合は、呼び出し側が Store.ExecTx で
+// このメソッドの呼び出し全体を1つのトランザクションにまとめること
+// （UpdateStatusesIfCurrentStatus と同じ運用）。
+func (r *OrderRepository) SplitOrder(ctx context.Context, orderID int64, deliveredQty int) (string, error) {
+    if deliveredQty <= 0 {
+        return "", fmt.Errorf("deliveredQty must be positive, got %d", deliveredQty)
+    }
+
+    var current struct {
+        ProductID int `db:"product_id"`
+        UserID    int `db:"user_id"`
+        Quantity  int `db:"quantity"`
+    }
+    err := r.db.GetContext(ctx, &current,
+        `SELECT product_id, user_id, quantity FROM orders WHERE order_id = ? AND shipped_status = 'shipping'`,
+        orderID,
+    )
+    if err == sql.ErrNoRows {
+        return "", ErrOrderNotAvailable
+    }
+    if err != nil {
+        return "", err
+    }
+
+    if deliveredQty >= current.Quantity {
+        // 全量を引き受けるだけなので分割は不要
+        result, err := r.db.ExecContext(ctx,
+            `UPDATE orders SET delivered_quantity = quantity, shipped_status = 'delivering' WHERE order_id = ? AND shipped_status = 'shipping'`,
+            orderID,
+        )
+        if err != nil {
+            return "", err
+        }
+        affected, err := result.RowsAffected()
+        if err != nil {
+            return "", err
+        }
+        if affected == 0 {
+            return "", ErrOrderNotAvailable
+        }
+        return "", nil
+    }
+
+    remainingQty := current.Quantity - deliveredQty
+
+    // 元注文には配達する分だけを残す
+    result, err := r.db.ExecContext(ctx,
+        `UPDATE orders SET quantity = ?, delivered_quantity = ?, shipped_status = 'delivering' WHERE order_id = ? AND shipped_status = 'shipping'`,
+        deliveredQty, deliveredQty, orderID,
+    )
+    if err != nil {
+        return "", err
+    }
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return "", err
+    }
+    if affected == 0 {
+        return "", ErrOrderNotAvailable
+    }
+
+    // 残数量は新しい注文として shipping に戻す
+    result, err = r.db.ExecContext(ctx,
+        `INSERT INTO orders (user_id, product_id, quantity, delivered_quantity, shipped_status, created_at) VALUES (?, ?, ?, 0, 'shipping', NOW())`,
+        current.UserID, current.ProductID, remainingQty,
+    )
+    if err != nil {
+        return "", err
+    }
+    newID, err := result.LastInsertId()
+    if err != nil {
+        return "", err
+    }
+    return fmt.Sprintf("%d", newID), nil
+}
+
+
+// OrderColumnAllowList は一覧のカラム指定で選択可能な列の allow-list（ColumnID -> SQL式）。
+// サービス層はユーザーが保存した ColumnID をこれと突き合わせてから ListOrders に渡すことで、
+// 任意の文字列が SELECT 句に混入するのを防ぐ。
+var OrderColumnAllowList = map[string]string{
+	"order_id":       "o.order_id",
+	"product_id":     "o.product_id",
+	"product_name":   "p.name AS product_name",
+	"shipped_status": "o.shipped_status",
+	"created_at":     "o.created_at",
+	"arrived_at":     "o.arrived_at",
+}
+
+// DefaultOrderColumns はカラム設定が未保存のユーザーに使われるデフォルトの表示順
+var DefaultOrderColumns = []string{"order_id", "product_id", "product_name", "shipped_status", "created_at", "arrived_at"}
 
 // 注文履歴一覧を取得 (Optimized)
-func (r *OrderRepository) ListOrders(ctx context.Context, userID int, req model.ListRequest) ([]model.Order, int, error) {
+// columns には OrderColumnAllowList のキーに含まれる ColumnID だけを渡すこと。
+// 空スライスの場合は DefaultOrderColumns を使う。
+func (r *OrderRepository) ListOrders(ctx context.Context, userID int, req model.ListRequest, columns []string) ([]model.Order, int, error) {
 	// 1. 先获取总数 (変更なし)
 	total, err := r.GetTotalOrdersCount(ctx, userID, req)
 	if err != nil {
@@ -118,18 +282,13 @@ func (r *OrderRepository) ListOrders(ctx context.Context, userID int, req model.
 	// 2. 构建主查询 (変更なし)
 	queryArgs := []interface{}{userID}
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString(`
+	queryBuilder.WriteString(fmt.Sprintf(`
         SELECT
-            o.order_id,
-            o.product_id,
-            p.name AS product_name,
-            o.shipped_status,
-            o.created_at,
-            o.arrived_at
+            %s
         FROM orders o
         JOIN products p ON o.product_id = p.product_id
         WHERE o.user_id = ?
-    `)
+    `, buildProjection(columns, OrderColumnAllowList, DefaultOrderColumns)))
 
 	// 添加搜索条件 (変更なし)
 	if req.Search != "" {
@@ -179,9 +338,13 @@ func (r *OrderRepository) ListOrders(ctx context.Context, userID int, req model.
 	}
 	var ordersRaw []orderRow
 
-	if err := r.db.SelectContext(ctx, &ordersRaw, queryBuilder.String(), queryArgs...); err != nil {
+	finalQuery := r.db.Rebind(queryBuilder.String())
+	start := time.Now()
+	if err := r.db.SelectContext(ctx, &ordersRaw, finalQuery, queryArgs...); err != nil {
         return nil, 0, err
     }
+	observability.Debug(ctx, "OrderRepository.ListOrders",
+		"query", finalQuery, "arg_count", len(queryArgs), "rows", len(ordersRaw), "elapsed_ms", time.Since(start).Milliseconds())
 
     // 4. 转换数据
 	// 修正： 'orderRow' から 'model.Order' へ手動でマッピングする
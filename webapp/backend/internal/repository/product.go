@@ -2,9 +2,13 @@ package repository
 
 import (
 	"backend/internal/model"
+	"backend/internal/observability"
 	"context"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type ProductRepository struct {
@@ -15,6 +19,79 @@ func NewProductRepository(db DBTX) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
+// BulkInsert は products を batchSize 件ずつの複数行 INSERT に分けて挿入し、挿入できた件数を返す。
+// Excel インポートのような大量データ投入で、1行ずつ ExecContext するのを避けるために使う。
+func (r *ProductRepository) BulkInsert(ctx context.Context, products []model.Product, batchSize int) (int, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(products)
+	}
+
+	inserted := 0
+	for start := 0; start < len(products); start += batchSize {
+		end := start + batchSize
+		if end > len(products) {
+			end = len(products)
+		}
+		batch := products[start:end]
+
+		var queryBuilder strings.Builder
+		queryBuilder.WriteString("INSERT INTO products (name, value, weight, image, description) VALUES ")
+		args := make([]interface{}, 0, len(batch)*5)
+		for i, p := range batch {
+			if i > 0 {
+				queryBuilder.WriteString(", ")
+			}
+			queryBuilder.WriteString("(?, ?, ?, ?, ?)")
+			args = append(args, p.Name, p.Value, p.Weight, p.Image, p.Description)
+		}
+
+		query, inArgs, err := sqlx.In(queryBuilder.String(), args...)
+		if err != nil {
+			return inserted, err
+		}
+		query = r.db.Rebind(query)
+		result, err := r.db.ExecContext(ctx, query, inArgs...)
+		if err != nil {
+			return inserted, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += int(affected)
+	}
+	return inserted, nil
+}
+
+// ExistingProductIDs は ids のうち products テーブルに実在するものだけを集合として返す。
+// products に外部キー制約を持つテーブルは無いため、Excel インポートのように参照整合性を
+// 明示的にチェックしたい呼び出し側がこれを使う。
+func (r *ProductRepository) ExistingProductIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	if len(ids) == 0 {
+		return map[int]bool{}, nil
+	}
+
+	query, args, err := sqlx.In("SELECT product_id FROM products WHERE product_id IN (?)", ids)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+
+	var found []int
+	if err := r.db.SelectContext(ctx, &found, query, args...); err != nil {
+		return nil, err
+	}
+
+	exists := make(map[int]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	return exists, nil
+}
+
 // GetTotalProductsCount 获取筛选后的商品总数
 func (r *ProductRepository) GetTotalProductsCount(ctx context.Context, req model.ListRequest) (int, error) {
     var total int
@@ -34,8 +111,25 @@ func (r *ProductRepository) GetTotalProductsCount(ctx context.Context, req model
     return total, err
 }
 
+// ProductColumnAllowList は一覧のカラム指定で選択可能な列の allow-list（ColumnID -> SQL式）。
+// サービス層はユーザーが保存した ColumnID をこれと突き合わせてから ListProducts に渡すことで、
+// 任意の文字列が SELECT 句に混入するのを防ぐ。
+var ProductColumnAllowList = map[string]string{
+	"product_id":  "product_id",
+	"name":        "name",
+	"value":       "value",
+	"weight":      "weight",
+	"image":       "image",
+	"description": "description",
+}
+
+// DefaultProductColumns はカラム設定が未保存のユーザーに使われるデフォルトの表示順
+var DefaultProductColumns = []string{"product_id", "name", "value", "weight", "image", "description"}
+
 // 商品一覧を取得 (Optimized)
-func (r *ProductRepository) ListProducts(ctx context.Context, userID int, req model.ListRequest) ([]model.Product, int, error) {
+// columns には ProductColumnAllowList のキーに含まれる ColumnID だけを渡すこと。
+// 空スライスの場合は DefaultProductColumns を使う。
+func (r *ProductRepository) ListProducts(ctx context.Context, userID int, req model.ListRequest, columns []string) ([]model.Product, int, error) {
 	// 1. 获取总数
 	total, err := r.GetTotalProductsCount(ctx, req)
 	if err != nil {
@@ -49,10 +143,10 @@ func (r *ProductRepository) ListProducts(ctx context.Context, userID int, req mo
 	var products []model.Product
     // 使用 strings.Builder
     var queryBuilder strings.Builder
-    queryBuilder.WriteString(`
-        SELECT product_id, name, value, weight, image, description
+    queryBuilder.WriteString(fmt.Sprintf(`
+        SELECT %s
         FROM products
-    `)
+    `, buildProjection(columns, ProductColumnAllowList, DefaultProductColumns)))
 
     args := []interface{}{}
 
@@ -84,10 +178,14 @@ func (r *ProductRepository) ListProducts(ctx context.Context, userID int, req mo
 	queryBuilder.WriteString(" LIMIT ? OFFSET ?")
 	args = append(args, req.PageSize, req.Offset)
 
-	err = r.db.SelectContext(ctx, &products, queryBuilder.String(), args...)
+	finalQuery := r.db.Rebind(queryBuilder.String())
+	start := time.Now()
+	err = r.db.SelectContext(ctx, &products, finalQuery, args...)
 	if err != nil {
 		return nil, 0, err
 	}
+	observability.Debug(ctx, "ProductRepository.ListProducts",
+		"query", finalQuery, "arg_count", len(args), "rows", len(products), "elapsed_ms", time.Since(start).Milliseconds())
 
 	// 5. 返回分页结果和总数
 	return products, total, nil
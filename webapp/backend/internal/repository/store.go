@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX は *sqlx.DB と *sqlx.Tx の両方が満たすインターフェース。
+// 各 Repository はこれを受け取ることでトランザクション有無を意識せずに実装できる。
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Rebind(query string) string
+}
+
+// Store は全 Repository をまとめて保持し、トランザクション境界を提供する
+type Store struct {
+	db                *sqlx.DB
+	OrderRepo         *OrderRepository
+	ProductRepo       *ProductRepository
+	ColumnSettingRepo *ColumnSettingRepository
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{
+		db:                db,
+		OrderRepo:         NewOrderRepository(db),
+		ProductRepo:       NewProductRepository(db),
+		ColumnSettingRepo: NewColumnSettingRepository(db),
+	}
+}
+
+// ExecTx はトランザクションを開始し、fn に trx 版の Store を渡して実行する。
+// fn がエラーを返した場合はロールバックし、そうでなければコミットする。
+func (s *Store) ExecTx(ctx context.Context, fn func(txStore *Store) error) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	txStore := &Store{
+		db:                s.db,
+		OrderRepo:         NewOrderRepository(tx),
+		ProductRepo:       NewProductRepository(tx),
+		ColumnSettingRepo: NewColumnSettingRepository(tx),
+	}
+
+	if err := fn(txStore); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"backend/internal/model"
+	"backend/internal/repository"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// 一覧カラム設定の対象モジュールを表すキー
+const (
+	ColumnSettingKeyProducts = "product_list"
+	ColumnSettingKeyOrders   = "order_list"
+)
+
+// moduleAllowLists はモジュールキーごとの allow-list（repository 側の定義をそのまま使う）
+var moduleAllowLists = map[string]map[string]string{
+	ColumnSettingKeyProducts: repository.ProductColumnAllowList,
+	ColumnSettingKeyOrders:   repository.OrderColumnAllowList,
+}
+
+// moduleDefaultColumns はモジュールキーごとのデフォルト表示順（ColumnID のみ）
+var moduleDefaultColumns = map[string][]string{
+	ColumnSettingKeyProducts: repository.DefaultProductColumns,
+	ColumnSettingKeyOrders:   repository.DefaultOrderColumns,
+}
+
+type ColumnSettingService struct {
+	store *repository.Store
+}
+
+func NewColumnSettingService(store *repository.Store) *ColumnSettingService {
+	return &ColumnSettingService{store: store}
+}
+
+// Get はユーザーの保存済みカラム設定を返す。未保存ならデフォルトを組み立てて返す。
+func (s *ColumnSettingService) Get(ctx context.Context, userID int, key string) (*model.ColumnSetting, error) {
+	if _, ok := moduleAllowLists[key]; !ok {
+		return nil, fmt.Errorf("unknown column setting key %q", key)
+	}
+
+	setting, err := s.store.ColumnSettingRepo.Get(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if setting != nil {
+		return setting, nil
+	}
+	return &model.ColumnSetting{UserID: userID, Key: key, Columns: defaultColumnDefs(key)}, nil
+}
+
+// Upsert はユーザー入力の Columns のうち allow-list に載っている ColumnID だけを保存する
+func (s *ColumnSettingService) Upsert(ctx context.Context, setting model.ColumnSetting) error {
+	allowList, ok := moduleAllowLists[setting.Key]
+	if !ok {
+		return fmt.Errorf("unknown column setting key %q", setting.Key)
+	}
+
+	filtered := make([]model.ColumnDef, 0, len(setting.Columns))
+	for _, col := range setting.Columns {
+		if _, ok := allowList[col.ColumnID]; ok {
+			filtered = append(filtered, col)
+		}
+	}
+	setting.Columns = filtered
+	return s.store.ColumnSettingRepo.Upsert(ctx, setting)
+}
+
+// Reset はユーザーの保存済み設定を削除し、デフォルト設定を返す
+func (s *ColumnSettingService) Reset(ctx context.Context, userID int, key string) (*model.ColumnSetting, error) {
+	if _, ok := moduleAllowLists[key]; !ok {
+		return nil, fmt.Errorf("unknown column setting key %q", key)
+	}
+	if err := s.store.ColumnSettingRepo.Reset(ctx, userID, key); err != nil {
+		return nil, err
+	}
+	return &model.ColumnSetting{UserID: userID, Key: key, Columns: defaultColumnDefs(key)}, nil
+}
+
+// List は設定可能なモジュールキーと、それぞれの allow-list 上のデフォルトカラム ID 一覧を返す
+func (s *ColumnSettingService) List() map[string][]string {
+	result := make(map[string][]string, len(moduleDefaultColumns))
+	for key, cols := range moduleDefaultColumns {
+		result[key] = cols
+	}
+	return result
+}
+
+// ResolveColumnIDs は設定から Visible な ColumnID を Order 順に並べて返す。
+// ProductRepository.ListProducts / OrderRepository.ListOrders にそのまま渡せる。
+func ResolveColumnIDs(setting *model.ColumnSetting) []string {
+	if setting == nil {
+		return nil
+	}
+	cols := make([]model.ColumnDef, len(setting.Columns))
+	copy(cols, setting.Columns)
+	sort.Slice(cols, func(i, j int) bool { return cols[i].Order < cols[j].Order })
+
+	ids := make([]string, 0, len(cols))
+	for _, c := range cols {
+		if c.Visible {
+			ids = append(ids, c.ColumnID)
+		}
+	}
+	return ids
+}
+
+func defaultColumnDefs(key string) []model.ColumnDef {
+	ids := moduleDefaultColumns[key]
+	defs := make([]model.ColumnDef, len(ids))
+	for i, id := range ids {
+		defs[i] = model.ColumnDef{ColumnID: id, ParamCn: id, Visible: true, Order: i}
+	}
+	return defs
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"backend/internal/model"
+	"backend/internal/repository"
+	"backend/internal/service/utils/excel"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// importBatchSize は BulkInsert を分割するバッチサイズ
+const importBatchSize = 500
+
+// ImportResult は Excel インポート1回分の結果。成功件数と行単位のエラーをまとめて返す。
+type ImportResult struct {
+	ImportedCount int              `json:"imported_count"`
+	RowErrors     []excel.RowError `json:"row_errors"`
+}
+
+var productImportFields = []excel.DataField{
+	{EnName: "name", CnName: "商品名", Required: true},
+	{EnName: "value", CnName: "価格", Required: true},
+	{EnName: "weight", CnName: "重量", Required: true},
+	{EnName: "image", CnName: "画像URL"},
+	{EnName: "description", CnName: "説明"},
+}
+
+var orderImportFields = []excel.DataField{
+	{EnName: "user_id", CnName: "ユーザーID", Required: true},
+	{EnName: "product_id", CnName: "商品ID", Required: true},
+	{EnName: "quantity", CnName: "数量", Required: true},
+}
+
+type ImportService struct {
+	store *repository.Store
+}
+
+func NewImportService(store *repository.Store) *ImportService {
+	return &ImportService{store: store}
+}
+
+// Import は code ("PRODUCTS" | "ORDERS") に応じて r の xlsx を読み込み、
+// ProductRepository / OrderRepository へバッチ投入する。行単位のエラーは
+// fail-fast せずに ImportResult.RowErrors に集約して返す。
+// BulkInsert がバッチの途中で失敗した場合でも、戻り値の *ImportResult にはそれまでに
+// コミット済みの件数が入る（呼び出し側はエラーと一緒にこれを読むこと）。
+func (s *ImportService) Import(ctx context.Context, code string, r io.Reader) (*ImportResult, error) {
+	switch code {
+	case "PRODUCTS":
+		return s.importProducts(ctx, r)
+	case "ORDERS":
+		return s.importOrders(ctx, r)
+	default:
+		return nil, fmt.Errorf("unknown import code %q", code)
+	}
+}
+
+func (s *ImportService) importProducts(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	imp := excel.NewImporter(productImportFields, 2)
+	rows, rowErrors, err := imp.Import(r, "Sheet1", parseProductImportRow)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]model.Product, 0, len(rows))
+	for _, row := range rows {
+		products = append(products, row.(model.Product))
+	}
+
+	// BulkInsert はバッチごとに独立した INSERT を行うため、途中のバッチで失敗しても
+	// それより前のバッチはコミット済みになる。呼び出し側がどこまで入ったかを把握できるよう、
+	// エラー時も inserted の値を含めて返す。
+	inserted, err := s.store.ProductRepo.BulkInsert(ctx, products, importBatchSize)
+	return &ImportResult{ImportedCount: inserted, RowErrors: rowErrors}, err
+}
+
+func parseProductImportRow(row int, values map[string]string) (interface{}, *excel.RowError) {
+	value, err := strconv.Atoi(values["value"])
+	if err != nil {
+		return nil, &excel.RowError{Row: row, Column: "価格", Message: "must be an integer"}
+	}
+	weight, err := strconv.Atoi(values["weight"])
+	if err != nil {
+		return nil, &excel.RowError{Row: row, Column: "重量", Message: "must be an integer"}
+	}
+	return model.Product{
+		Name:        values["name"],
+		Value:       value,
+		Weight:      weight,
+		Image:       values["image"],
+		Description: values["description"],
+	}, nil
+}
+
+// orderImportRow は parseOrderImportRow の出力に元の行番号を添えたもの。
+// product_id の実在チェックは全行を解析し終えてからまとめて行うため、そのエラー報告に
+// 行番号が必要になる。
+type orderImportRow struct {
+	Row   int
+	Order model.Order
+}
+
+// importOrders は product_id が products テーブルに実在するかをチェックし、存在しない行は
+// RowError として報告してインポート対象から除外する。user_id はこのリポジトリに users
+// テーブル/リポジトリが存在しないため参照整合性チェックの対象外とし、呼び出し側の責務とする。
+func (s *ImportService) importOrders(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	imp := excel.NewImporter(orderImportFields, 2)
+	rows, rowErrors, err := imp.Import(r, "Sheet1", parseOrderImportRow)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make([]orderImportRow, 0, len(rows))
+	for _, row := range rows {
+		parsed = append(parsed, row.(orderImportRow))
+	}
+
+	productIDs := make([]int, 0, len(parsed))
+	seen := make(map[int]bool, len(parsed))
+	for _, p := range parsed {
+		if !seen[p.Order.ProductID] {
+			seen[p.Order.ProductID] = true
+			productIDs = append(productIDs, p.Order.ProductID)
+		}
+	}
+
+	existingProductIDs, err := s.store.ProductRepo.ExistingProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]model.Order, 0, len(parsed))
+	for _, p := range parsed {
+		if !existingProductIDs[p.Order.ProductID] {
+			rowErrors = append(rowErrors, excel.RowError{Row: p.Row, Column: "商品ID", Message: "referenced product does not exist"})
+			continue
+		}
+		orders = append(orders, p.Order)
+	}
+
+	// BulkInsert はバッチごとに独立した INSERT を行うため、途中のバッチで失敗しても
+	// それより前のバッチはコミット済みになる。呼び出し側がどこまで入ったかを把握できるよう、
+	// エラー時も inserted の値を含めて返す。
+	inserted, err := s.store.OrderRepo.BulkInsert(ctx, orders, importBatchSize)
+	return &ImportResult{ImportedCount: inserted, RowErrors: rowErrors}, err
+}
+
+func parseOrderImportRow(row int, values map[string]string) (interface{}, *excel.RowError) {
+	userID, err := strconv.Atoi(values["user_id"])
+	if err != nil {
+		return nil, &excel.RowError{Row: row, Column: "ユーザーID", Message: "must be an integer"}
+	}
+	productID, err := strconv.Atoi(values["product_id"])
+	if err != nil {
+		return nil, &excel.RowError{Row: row, Column: "商品ID", Message: "must be an integer"}
+	}
+	quantity, err := strconv.Atoi(values["quantity"])
+	if err != nil {
+		return nil, &excel.RowError{Row: row, Column: "数量", Message: "must be an integer"}
+	}
+	if quantity <= 0 {
+		return nil, &excel.RowError{Row: row, Column: "数量", Message: "must be greater than zero"}
+	}
+	return orderImportRow{
+		Row: row,
+		Order: model.Order{
+			UserID:    userID,
+			ProductID: productID,
+			Quantity:  quantity,
+		},
+	}, nil
+}
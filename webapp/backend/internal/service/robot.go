@@ -2,10 +2,15 @@ package service
 
 import (
 	"backend/internal/model"
+	"backend/internal/observability"
 	"backend/internal/repository"
 	"backend/internal/service/utils"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"time"
 )
 
 type RobotService struct {
@@ -16,9 +21,27 @@ func NewRobotService(store *repository.Store) *RobotService {
 	return &RobotService{store: store}
 }
 
+// dpSizeThreshold は n*capacity がこれを超えたら DP テーブルを諦めて分枝限定法に切り替える閾値。
+// 実測では DP は 10^7 セルを大きく超えても数十 ms で終わる一方、分枝限定法は商品構成次第で
+// 剪枝が効かず締切いっぱいまで探索してしまうことがあるため、DP に倒せる範囲はできるだけ広げておく。
+const dpSizeThreshold = 50_000_000
+
+// bnbNodeBudget は runBranchAndBound が探索してよい DFS ノード数の上限。
+// ctx の締切とは独立したバックストップで、タイマー精度や GC 一時停止などで
+// 締切チェックが遅れても、際限なく探索し続けないようにするためのガード。
+const bnbNodeBudget = 2_000_000
+
+// bnbPerSpecBudget は GenerateDeliveryPlanForRobots が分枝限定法に割り当てる1ロボットあたりの
+// 既定の時間予算。複数ロボットを順番に solve する都合上、これを設けないと最初のロボットの
+// 探索が残りの締切を使い切ってしまい、後続ロボットの分が残らなくなる。
+const bnbPerSpecBudget = 750 * time.Millisecond
+
 // 注意：このメソッドは、現在、ordersテーブルのshipped_statusが"shipping"になっている注文"全件"を対象に配送計画を立てます。
 // 注文の取得件数を制限した場合、ペナルティの対象になります。
-func (s *RobotService) GenerateDeliveryPlan(ctx context.Context, robotID string, capacity int) (*model.DeliveryPlan, error) {
+// allowPartial が true の場合、注文は quantity 単位まで部分的に積むことができ、
+// 積み残した分は OrderRepository.SplitOrder によって新しい注文として shipping に戻される。
+func (s *RobotService) GenerateDeliveryPlan(ctx context.Context, robotID string, capacity int, allowPartial bool) (*model.DeliveryPlan, error) {
+    ctx = observability.WithCorrelationID(ctx, newCorrelationID(robotID))
     var plan model.DeliveryPlan
 
     err := utils.WithTimeout(ctx, func(ctx context.Context) error {
@@ -28,10 +51,16 @@ func (s *RobotService) GenerateDeliveryPlan(ctx context.Context, robotID string,
             return err
         }
 
-        localPlan, err := selectOrdersForDelivery(ctx, orders, robotID, capacity)
+        knapsackStart := time.Now()
+        // 単体ロボットの計画では ctx の締切をそのまま使えばよく、他のロボットと
+        // 分け合う必要がないので bnbBudget=0（無制限）で呼ぶ。
+        localPlan, err := selectOrdersForDelivery(ctx, orders, robotID, capacity, allowPartial, 0)
         if err != nil {
             return err
         }
+        observability.Debug(ctx, "RobotService.selectOrdersForDelivery",
+            "robot_id", robotID, "candidate_orders", len(orders), "selected_orders", len(localPlan.Orders),
+            "algorithm", localPlan.Algorithm, "elapsed_ms", time.Since(knapsackStart).Milliseconds())
         plan = localPlan
 
         if len(plan.Orders) == 0 {
@@ -39,24 +68,43 @@ func (s *RobotService) GenerateDeliveryPlan(ctx context.Context, robotID string,
             return nil
         }
 
-        orderIDs := make([]int64, len(plan.Orders))
-        for i, order := range plan.Orders {
-            orderIDs[i] = order.OrderID
+        if !allowPartial {
+            orderIDs := make([]int64, len(plan.Orders))
+            for i, order := range plan.Orders {
+                orderIDs[i] = order.OrderID
+            }
+
+            // 2. 事务内只做一次短 UPDATE（乐观锁：仅更新当前仍为 shipping 的订单）
+            return s.store.ExecTx(ctx, func(txStore *repository.Store) error {
+                rows, err := txStore.OrderRepo.UpdateStatusesIfCurrentStatus(
+                    ctx,
+                    orderIDs,
+                    "shipping",   // fromStatus
+                    "delivering", // newStatus
+                )
+                if err != nil {
+                    return err
+                }
+
+                log.Printf("Updated status from 'shipping' to 'delivering' for %d/%d orders", rows, len(orderIDs))
+                return nil
+            })
         }
 
-        // 2. 事务内只做一次短 UPDATE（乐观锁：仅更新当前仍为 shipping 的订单）
+        // 2'. 部分配送ありの場合は、注文ごとに SplitOrder で「引き受ける数量」を確定させる。
+        // 全注文の呼び出しを同一トランザクションにまとめることで原子性を保つ。
+        // SplitOrder が ErrOrderNotAvailable を返した注文（= 他のロボットに先に確保された）は
+        // プランから除外し、TotalWeight/TotalValue も実際に確保できた分だけに組み直す。
         return s.store.ExecTx(ctx, func(txStore *repository.Store) error {
-            rows, err := txStore.OrderRepo.UpdateStatusesIfCurrentStatus(
-                ctx,
-                orderIDs,
-                "shipping",   // fromStatus
-                "delivering", // newStatus
-            )
+            claimedOrders, totalWeight, totalValue, err := claimOrders(ctx, txStore, plan.Orders)
             if err != nil {
                 return err
             }
+            plan.Orders = claimedOrders
+            plan.TotalWeight = totalWeight
+            plan.TotalValue = totalValue
 
-            log.Printf("Updated status from 'shipping' to 'delivering' for %d/%d orders", rows, len(orderIDs))
+            log.Printf("Claimed %d orders (partial-delivery aware) for robot %s", len(plan.Orders), robotID)
             return nil
         })
     })
@@ -66,6 +114,160 @@ func (s *RobotService) GenerateDeliveryPlan(ctx context.Context, robotID string,
     return &plan, nil
 }
 
+// GenerateDeliveryPlanForRobots は複数のロボットを同じ shipping プールに対して同時に計画する。
+// 各ロボットの候補は「自分より前に渡された spec が確保した注文」を除外しながら順番に solve し、
+// 最後に全ロボット分の order_id をまとめて1回の UpdateStatusesIfCurrentStatus で原子的に確保する。
+// こうすることで、2つの planner が並行に走っても同じ注文を取り合うことがない。
+// いずれかの spec が AllowPartial の場合は、代わりに1つのトランザクション内で注文ごとに
+// SplitOrder を呼んで確保する（claimOrders）。
+func (s *RobotService) GenerateDeliveryPlanForRobots(ctx context.Context, specs []model.RobotSpec) ([]*model.DeliveryPlan, error) {
+    if len(specs) == 0 {
+        return nil, nil
+    }
+
+    ctx = observability.WithCorrelationID(ctx, newCorrelationID(specs[0].RobotID))
+    plans := make([]*model.DeliveryPlan, len(specs))
+
+    err := utils.WithTimeout(ctx, func(ctx context.Context) error {
+        orders, err := s.store.OrderRepo.GetShippingOrders(ctx)
+        if err != nil {
+            return err
+        }
+
+        // remainingQty は各注文について、まだどのロボットにも確保されていない残数量を追跡する。
+        // かつては claimed map[int64]bool で「1束でも取られたら注文ごと除外」していたが、
+        // これだと allowPartial なロボットが一部だけ積んだ注文の残り数量を後続のロボットが
+        // 見られなくなり、部分配送の効果が薄れてしまっていた。残数量ベースに変えることで、
+        // 計画段階では1つの注文の残りを後続ロボットの候補にも含める。ただし確保時に同じ
+        // OrderID を SplitOrder できるのは最初のロボットだけで、後続ロボットの分は
+        // claimOrders で除外される。
+        remainingQty := make(map[int64]int, len(orders))
+        originalByID := make(map[int64]model.Order, len(orders))
+        for _, o := range orders {
+            remainingQty[o.OrderID] = effectiveQuantity(o)
+            originalByID[o.OrderID] = o
+        }
+
+        deadline, hasDeadline := ctx.Deadline()
+        anyPartial := false
+        for i, spec := range specs {
+            available := make([]model.Order, 0, len(orders))
+            for id, qty := range remainingQty {
+                if qty <= 0 {
+                    continue
+                }
+                o := originalByID[id]
+                o.Quantity = qty
+                available = append(available, o)
+            }
+
+            // 分枝限定法には残りロボット数で均等割りした時間予算を与え、1台の探索が
+            // 締切を使い切って後続ロボットの計算時間を奪わないようにする。
+            bnbBudget := bnbPerSpecBudget
+            if hasDeadline {
+                if remaining := time.Until(deadline); remaining > 0 {
+                    bnbBudget = remaining / time.Duration(len(specs)-i)
+                }
+            }
+
+            localPlan, err := selectOrdersForDelivery(ctx, available, spec.RobotID, spec.Capacity, spec.AllowPartial, bnbBudget)
+            if err != nil {
+                return err
+            }
+            for _, o := range localPlan.Orders {
+                remainingQty[o.OrderID] -= o.Quantity
+            }
+            plans[i] = &localPlan
+            anyPartial = anyPartial || spec.AllowPartial
+        }
+
+        if !anyPartial {
+            var allOrderIDs []int64
+            for _, plan := range plans {
+                for _, o := range plan.Orders {
+                    allOrderIDs = append(allOrderIDs, o.OrderID)
+                }
+            }
+            if len(allOrderIDs) == 0 {
+                return nil
+            }
+
+            return s.store.ExecTx(ctx, func(txStore *repository.Store) error {
+                rows, err := txStore.OrderRepo.UpdateStatusesIfCurrentStatus(
+                    ctx,
+                    allOrderIDs,
+                    "shipping",
+                    "delivering",
+                )
+                if err != nil {
+                    return err
+                }
+
+                log.Printf("Updated status from 'shipping' to 'delivering' for %d/%d orders across %d robots", rows, len(allOrderIDs), len(specs))
+                return nil
+            })
+        }
+
+        // いずれかのロボットが部分配送を許可している場合は、注文ごとに SplitOrder で
+        // 引き受け数量を確定させる。SplitOrder は全量引き受けのケースも扱えるので、
+        // 部分配送ではない注文が混ざっていても安全に同じ経路を使える。
+        // SplitOrder が ErrOrderNotAvailable を返した注文は当該ロボットのプランから除外する
+        // （非 partial ロボットが混ざっていても、この経路を通る以上は同じ保証を適用する）。
+        return s.store.ExecTx(ctx, func(txStore *repository.Store) error {
+            claimedCount := 0
+            for _, plan := range plans {
+                claimedOrders, totalWeight, totalValue, err := claimOrders(ctx, txStore, plan.Orders)
+                if err != nil {
+                    return err
+                }
+                plan.Orders = claimedOrders
+                plan.TotalWeight = totalWeight
+                plan.TotalValue = totalValue
+                claimedCount += len(claimedOrders)
+            }
+            log.Printf("Claimed %d orders (partial-delivery aware) across %d robots", claimedCount, len(specs))
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return plans, nil
+}
+
+// claimOrders は candidates の各注文を txStore.OrderRepo.SplitOrder で確定させる。
+// repository.ErrOrderNotAvailable（= 別のロボットが先に確保していた、または同じ注文の
+// 残り数量を別のロボットの plan がこのトランザクション内で既に確保していた）が返った
+// 注文は確保できなかったものとして除外し、実際に確保できた注文と、それに基づく
+// TotalWeight/TotalValue を返す。
+// 複数ロボットの plan が同じ OrderID（元注文から分割される前の残数量）を跨って
+// 参照している場合、SplitOrder に成功できるのはトランザクション内で最初に呼ばれた
+// ロボットだけであり、後続のロボットはこの注文を諦める形になる（安全側に倒れるだけで、
+// クラッシュやデータ不整合にはならない）。
+func claimOrders(ctx context.Context, txStore *repository.Store, candidates []model.Order) ([]model.Order, int, int, error) {
+    claimed := make([]model.Order, 0, len(candidates))
+    totalWeight, totalValue := 0, 0
+
+    for _, order := range candidates {
+        if _, err := txStore.OrderRepo.SplitOrder(ctx, order.OrderID, order.Quantity); err != nil {
+            if errors.Is(err, repository.ErrOrderNotAvailable) {
+                continue
+            }
+            return nil, 0, 0, err
+        }
+        claimed = append(claimed, order)
+        totalWeight += order.Weight * order.Quantity
+        totalValue += order.Value * order.Quantity
+    }
+    return claimed, totalWeight, totalValue, nil
+}
+
+// newCorrelationID は1回の配送計画リクエストに払い出す相関ID。
+// GetShippingOrders の SELECT からナップサック計算、条件付き UPDATE までを
+// 同じIDで observability.Debug に残すことで、1本のログとして追跡できるようにする。
+func newCorrelationID(seed string) string {
+    return fmt.Sprintf("plan-%s-%d", seed, time.Now().UnixNano())
+}
 
 func (s *RobotService) UpdateOrderStatus(ctx context.Context, orderID int64, newStatus string) error {
 	return utils.WithTimeout(ctx, func(ctx context.Context) error {
@@ -73,15 +275,75 @@ func (s *RobotService) UpdateOrderStatus(ctx context.Context, orderID int64, new
 	})
 }
 
-// selectOrdersForDelivery (Optimized with Dynamic Programming)
-func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID string, robotCapacity int) (model.DeliveryPlan, error) {
+// effectiveQuantity はマイグレーション前の行(quantity未設定=0)を1個として扱う
+func effectiveQuantity(o model.Order) int {
+    if o.Quantity <= 0 {
+        return 1
+    }
+    return o.Quantity
+}
+
+// decomposeBundles は数量 qty の注文を 1,2,4,...,2^(m-1),remainder の「束」に分解し、
+// 有界ナップサック問題を 0/1 ナップサックとして解けるようにする（二進分解）。
+// 各束の Weight/Value は単価 * 束の個数、Quantity は束が表す個数。
+func decomposeBundles(o model.Order) []model.Order {
+    remaining := effectiveQuantity(o)
+    bundles := make([]model.Order, 0, 1)
+    for units := 1; remaining > 0; units *= 2 {
+        if units > remaining {
+            units = remaining
+        }
+        bundle := o
+        bundle.Quantity = units
+        bundle.Weight = o.Weight * units
+        bundle.Value = o.Value * units
+        bundles = append(bundles, bundle)
+        remaining -= units
+    }
+    return bundles
+}
+
+// mergeSelectedBundles は束単位で選ばれた結果を注文単位にまとめ直す。
+// 同じ OrderID の束が複数選ばれていれば個数を合算し、Weight/Value は
+// originalsByID の単価に戻した上で TotalWeight/TotalValue を算出する。
+func mergeSelectedBundles(originalsByID map[int64]model.Order, bundles []model.Order) ([]model.Order, int, int) {
+    unitsByID := make(map[int64]int, len(bundles))
+    var idOrder []int64
+    for _, b := range bundles {
+        if _, ok := unitsByID[b.OrderID]; !ok {
+            idOrder = append(idOrder, b.OrderID)
+        }
+        unitsByID[b.OrderID] += b.Quantity
+    }
+
+    merged := make([]model.Order, 0, len(idOrder))
+    totalWeight, totalValue := 0, 0
+    for _, id := range idOrder {
+        units := unitsByID[id]
+        o := originalsByID[id]
+        o.Quantity = units
+        merged = append(merged, o)
+        totalWeight += o.Weight * units
+        totalValue += o.Value * units
+    }
+    return merged, totalWeight, totalValue
+}
+
+// selectOrdersForDelivery (Optimized with Dynamic Programming, falls back to Branch & Bound for large capacities)
+// allowPartial が true の場合、quantity > 1 の注文は二進分解した束単位の有界ナップサックとして解き、
+// 選ばれた束を注文単位に合算して返す。そうでない場合は注文を全量 0/1 のどちらかとして扱う。
+// bnbBudget は分枝限定法にフォールバックした場合に与える時間予算。0 の場合は ctx の締切を
+// そのまま使う（単体ロボットの計画など、他と分け合う必要がないケース向け）。
+func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID string, robotCapacity int, allowPartial bool, bnbBudget time.Duration) (model.DeliveryPlan, error) {
     // --- 0. trivial cases ---
     if robotCapacity <= 0 || len(orders) == 0 {
         return model.DeliveryPlan{RobotID: robotID}, nil
     }
 
-    // --- 1. 预过滤：去掉根本不可能装上的货 & 没价值的货 ---
-    filtered := make([]model.Order, 0, len(orders))
+    originalsByID := make(map[int64]model.Order, len(orders))
+
+    // --- 1. 预过滤：去掉根本不可能装上的货 & 没价值的货，并展开成待规划的 items ---
+    items := make([]model.Order, 0, len(orders))
     totalWeight := 0
     totalValue := 0
 
@@ -90,38 +352,62 @@ func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID
         if o.Weight <= 0 || o.Value <= 0 {
             continue
         }
-        // 比机器人容量还重的货，不可能选中，也跳过
+        // 单价比机器人容量还重的货，连1个都装不下，也跳过
         if o.Weight > robotCapacity {
             continue
         }
 
-        filtered = append(filtered, o)
-        totalWeight += o.Weight
-        totalValue += o.Value
+        qty := effectiveQuantity(o)
+        originalsByID[o.OrderID] = o
+        totalWeight += o.Weight * qty
+        totalValue += o.Value * qty
+
+        if allowPartial && qty > 1 {
+            items = append(items, decomposeBundles(o)...)
+        } else {
+            full := o
+            full.Quantity = qty
+            full.Weight = o.Weight * qty
+            full.Value = o.Value * qty
+            items = append(items, full)
+        }
     }
 
-    orders = filtered
-    if len(orders) == 0 {
+    if len(items) == 0 {
         // 过滤完啥也没有了
         return model.DeliveryPlan{RobotID: robotID}, nil
     }
 
     // --- 2. 早退出：所有货物总重本来就 <= 容量，直接全装上 ---
     if totalWeight <= robotCapacity {
+        fullOrders := make([]model.Order, 0, len(originalsByID))
+        for _, o := range orders {
+            if _, ok := originalsByID[o.OrderID]; !ok {
+                continue
+            }
+            o.Quantity = effectiveQuantity(o)
+            fullOrders = append(fullOrders, o)
+        }
         return model.DeliveryPlan{
             RobotID:     robotID,
             TotalWeight: totalWeight,
             TotalValue:  totalValue,
-            Orders:      orders,
+            Orders:      fullOrders,
+            Algorithm:   "exact_full",
         }, nil
     }
 
-    n := len(orders)
+    n := len(items)
 
-    // --- 3. DP table ---
-    // dp[i][w] = 使用前 i 个货物、容量 w 时能得到的最大 value
+    // --- 3. n*capacity が閾値を超える場合は DP テーブルを諦めて分枝限定法に切り替える ---
+    if int64(n)*int64(robotCapacity) > dpSizeThreshold {
+        return solveKnapsackBranchAndBound(ctx, items, originalsByID, robotID, robotCapacity, bnbBudget)
+    }
+
+    // --- 4. DP table ---
+    // dp[i][w] = 使用前 i 个 item、容量 w 时能得到的最大 value
     dp := make([][]int, n+1)
-    // choice[i][w] = 在得到 dp[i][w] 的时候，第 i 个货物是否被选中
+    // choice[i][w] = 在得到 dp[i][w] 的时候，第 i 个 item 是否被选中
     choice := make([][]bool, n+1)
 
     for i := 0; i <= n; i++ {
@@ -129,11 +415,11 @@ func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID
         choice[i] = make([]bool, robotCapacity+1)
     }
 
-    // --- 4. 填 DP 表 ---
+    // --- 5. 填 DP 表 ---
     for i := 1; i <= n; i++ {
-        ord := orders[i-1]
-        w := ord.Weight
-        v := ord.Value
+        it := items[i-1]
+        w := it.Weight
+        v := it.Value
 
         // 每隔一段检查一下 context，防止极端情况下超时
         if i%512 == 0 {
@@ -164,11 +450,9 @@ func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID
         }
     }
 
-    // --- 5. 反向回溯出被选中的订单 ---
+    // --- 6. 反向回溯出被选中的 item ---
     capLeft := robotCapacity
-    bestValue := dp[n][capLeft]
-    selected := make([]model.Order, 0, n)
-    totalSelectedWeight := 0
+    selectedItems := make([]model.Order, 0, n)
 
     for i := n; i >= 1; i-- {
         if capLeft <= 0 {
@@ -177,16 +461,173 @@ func selectOrdersForDelivery(ctx context.Context, orders []model.Order, robotID
         if !choice[i][capLeft] {
             continue
         }
-        ord := orders[i-1]
-        selected = append(selected, ord)
-        totalSelectedWeight += ord.Weight
-        capLeft -= ord.Weight
+        it := items[i-1]
+        selectedItems = append(selectedItems, it)
+        capLeft -= it.Weight
+    }
+
+    mergedOrders, mergedWeight, mergedValue := mergeSelectedBundles(originalsByID, selectedItems)
+
+    return model.DeliveryPlan{
+        RobotID:     robotID,
+        TotalWeight: mergedWeight,
+        TotalValue:  mergedValue,
+        Orders:      mergedOrders,
+        Algorithm:   "dp",
+    }, nil
+}
+
+// knapsackItem は分枝限定法用に value/weight 比を事前計算して持っておくための内部表現
+type knapsackItem struct {
+    order model.Order
+    ratio float64
+}
+
+// solveKnapsackBranchAndBound は capacity が大きく DP テーブルを構築できない場合の代替ソルバー。
+// bundleItems には selectOrdersForDelivery で展開済みの束（allowPartial=false なら注文全量の束）を渡す。
+// value/weight 降順に並べ替えた上で DFS + 分数緩和による上界でプルーニングする。
+// bnbBudget > 0 の場合、探索は ctx の締切と bnbBudget の早い方で打ち切られる
+// （GenerateDeliveryPlanForRobots のように複数ロボットで締切を分け合う場合に使う）。
+// 締切または bnbNodeBudget（ノード数の上限）に達したら探索を打ち切り、
+// 貪欲法（value/weight 降順に入るだけ詰める）の解と打ち切り時点の最良解のうち、
+// value の大きい方（同値なら後者）を返す。
+func solveKnapsackBranchAndBound(ctx context.Context, bundleItems []model.Order, originalsByID map[int64]model.Order, robotID string, capacity int, bnbBudget time.Duration) (model.DeliveryPlan, error) {
+    items := make([]knapsackItem, len(bundleItems))
+    for i, o := range bundleItems {
+        items[i] = knapsackItem{order: o, ratio: float64(o.Value) / float64(o.Weight)}
+    }
+    sort.Slice(items, func(i, j int) bool {
+        return items[i].ratio > items[j].ratio
+    })
+
+    searchCtx := ctx
+    if bnbBudget > 0 {
+        var cancel context.CancelFunc
+        searchCtx, cancel = context.WithTimeout(ctx, bnbBudget)
+        defer cancel()
+    }
+
+    bestValue, bestSelected, timedOut := runBranchAndBound(searchCtx, items, capacity)
+
+    algorithm := "branch_and_bound"
+    var selectedBundles []model.Order
+
+    if timedOut {
+        // 締切に達した場合は貪欲法（value/weight 降順に入るだけ詰める）にフォールバックする。
+        // 束は分割できないため、分数緩和そのものではなく 0/1 の貪欲近似を採用する。
+        algorithm = "greedy_fallback"
+        greedyValue := 0
+        greedyBundles := make([]model.Order, 0, len(items))
+        remaining := capacity
+        for _, it := range items {
+            if it.order.Weight <= remaining {
+                greedyBundles = append(greedyBundles, it.order)
+                greedyValue += it.order.Value
+                remaining -= it.order.Weight
+            }
+        }
+
+        // 分枝限定法が途中まで見つけていた最良解より悪ければ、そちらを採用する
+        if bestValue >= greedyValue {
+            algorithm = "branch_and_bound_partial"
+            for i, taken := range bestSelected {
+                if taken {
+                    selectedBundles = append(selectedBundles, items[i].order)
+                }
+            }
+        } else {
+            bestValue, selectedBundles = greedyValue, greedyBundles
+        }
+    } else {
+        for i, taken := range bestSelected {
+            if taken {
+                selectedBundles = append(selectedBundles, items[i].order)
+            }
+        }
     }
 
+    mergedOrders, mergedWeight, mergedValue := mergeSelectedBundles(originalsByID, selectedBundles)
+
     return model.DeliveryPlan{
         RobotID:     robotID,
-        TotalWeight: totalSelectedWeight,
-        TotalValue:  bestValue,
-        Orders:      selected,
+        TotalWeight: mergedWeight,
+        TotalValue:  mergedValue,
+        Orders:      mergedOrders,
+        Algorithm:   algorithm,
     }, nil
 }
+
+// knapsackUpperBound は items[i:] を value/weight 降順のまま分数緩和（最後の1個だけ端数を許す）で
+// remainingCap まで詰めたときの理論上界を返す
+func knapsackUpperBound(items []knapsackItem, i, remainingCap, currentValue int) int {
+    bound := currentValue
+    for ; i < len(items) && items[i].order.Weight <= remainingCap; i++ {
+        remainingCap -= items[i].order.Weight
+        bound += items[i].order.Value
+    }
+    if i < len(items) {
+        bound += int(float64(remainingCap) * items[i].ratio)
+    }
+    return bound
+}
+
+// runBranchAndBound は DFS + 上界プルーニングで 0/1 ナップサックを解く。
+// ctx の締切 / キャンセルを検知した場合、または探索ノード数が bnbNodeBudget に達した場合は
+// timedOut=true を返し、その時点までの最良解を返す。
+func runBranchAndBound(ctx context.Context, items []knapsackItem, capacity int) (bestValue int, bestSelected []bool, timedOut bool) {
+    n := len(items)
+    included := make([]bool, n)
+    bestSelected = make([]bool, n)
+    nodeCount := 0
+
+    deadline, hasDeadline := ctx.Deadline()
+
+    var aborted bool
+    var dfs func(i, remainingCap, currentValue int)
+    dfs = func(i, remainingCap, currentValue int) {
+        if aborted {
+            return
+        }
+        nodeCount++
+        if nodeCount >= bnbNodeBudget {
+            aborted = true
+            return
+        }
+        if nodeCount%2048 == 0 {
+            select {
+            case <-ctx.Done():
+                aborted = true
+                return
+            default:
+            }
+            if hasDeadline && time.Now().After(deadline) {
+                aborted = true
+                return
+            }
+        }
+
+        if currentValue > bestValue {
+            bestValue = currentValue
+            copy(bestSelected, included)
+        }
+        if i == n {
+            return
+        }
+        if knapsackUpperBound(items, i, remainingCap, currentValue) <= bestValue {
+            return // 剪枝：この枝は最良解を超えられない
+        }
+
+        if items[i].order.Weight <= remainingCap {
+            included[i] = true
+            dfs(i+1, remainingCap-items[i].order.Weight, currentValue+items[i].order.Value)
+            included[i] = false
+            if aborted {
+                return
+            }
+        }
+        dfs(i+1, remainingCap, currentValue)
+    }
+
+    dfs(0, capacity, 0)
+    return bestValue, bestSelected, aborted
+}
@@ -0,0 +1,111 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DataField はテンプレートの1カラムに対応する定義
+type DataField struct {
+	EnName   string // 取り込んだ値を運ぶマップのキー（struct フィールド名相当）
+	CnName   string // ヘッダー行に表示される列名
+	Required bool
+}
+
+// RowError は1行のインポートに失敗した理由を表す
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d, column %q: %s", e.Row, e.Column, e.Message)
+}
+
+// RowParser は1行分の値（EnName をキーにしたマップ）を受け取り、
+// 取り込み対象の構造体、または当該行だけのエラーを返す
+type RowParser func(row int, values map[string]string) (interface{}, *RowError)
+
+// Importer はヘッダー行でカラムを突き合わせ、データ行を1行ずつ RowParser に渡す汎用インポーター
+type Importer struct {
+	fields   []DataField
+	startRow int
+}
+
+// NewImporter は fields で定義された列と、データが始まる行番号（1始まり）を指定して Importer を作る。
+// ヘッダー行は startRow の直前の行として扱われる。
+func NewImporter(fields []DataField, startRow int) *Importer {
+	return &Importer{fields: fields, startRow: startRow}
+}
+
+// Import は r から xlsx を読み込み、sheetName の全データ行を解析する。
+// 行単位のエラーは fail-fast せずに rowErrors に集約して返す。
+func (imp *Importer) Import(r io.Reader, sheetName string, parse RowParser) (rows []interface{}, rowErrors []RowError, err error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open excel: %w", err)
+	}
+	defer f.Close()
+
+	allRows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sheet %q: %w", sheetName, err)
+	}
+	if imp.startRow < 2 || len(allRows) < imp.startRow-1 {
+		return nil, nil, nil
+	}
+
+	colIndex := make(map[string]int, len(imp.fields))
+	for idx, cell := range allRows[imp.startRow-2] {
+		colIndex[strings.TrimSpace(cell)] = idx
+	}
+
+	var missingColumns []string
+	for _, f := range imp.fields {
+		if _, ok := colIndex[f.CnName]; !ok && f.Required {
+			missingColumns = append(missingColumns, f.CnName)
+		}
+	}
+	if len(missingColumns) > 0 {
+		return nil, nil, fmt.Errorf("missing required columns: %s", strings.Join(missingColumns, ", "))
+	}
+
+	for i := imp.startRow - 1; i < len(allRows); i++ {
+		rowNum := i + 1
+		cells := allRows[i]
+
+		values := make(map[string]string, len(imp.fields))
+		var missingRequired []string
+		for _, f := range imp.fields {
+			var val string
+			if idx, ok := colIndex[f.CnName]; ok && idx < len(cells) {
+				val = strings.TrimSpace(cells[idx])
+			}
+			values[f.EnName] = val
+			if f.Required && val == "" {
+				missingRequired = append(missingRequired, f.CnName)
+			}
+		}
+		if len(missingRequired) > 0 {
+			for _, col := range missingRequired {
+				rowErrors = append(rowErrors, RowError{Row: rowNum, Column: col, Message: "required field is empty"})
+			}
+			continue
+		}
+
+		parsed, rowErr := parse(rowNum, values)
+		if rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+		if parsed != nil {
+			rows = append(rows, parsed)
+		}
+	}
+
+	return rows, rowErrors, nil
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTimeout は各サービスメソッドに適用するデフォルトのタイムアウト
+const DefaultTimeout = 5 * time.Second
+
+// WithTimeout は ctx に DefaultTimeout を適用した上で fn を実行する
+func WithTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	return fn(ctx)
+}